@@ -0,0 +1,20 @@
+// Package all imports every backend and middleware that ships with restic,
+// so that each one's init() function registers it with the location
+// package. Programs that only need a subset (or want to add their own) can
+// skip this package and import the individual packages instead.
+package all
+
+import (
+	_ "github.com/restic/restic/internal/backend/azure"
+	_ "github.com/restic/restic/internal/backend/b2"
+	_ "github.com/restic/restic/internal/backend/cache"
+	_ "github.com/restic/restic/internal/backend/gs"
+	_ "github.com/restic/restic/internal/backend/local"
+	_ "github.com/restic/restic/internal/backend/rclone"
+	_ "github.com/restic/restic/internal/backend/rest"
+	_ "github.com/restic/restic/internal/backend/retry"
+	_ "github.com/restic/restic/internal/backend/s3"
+	_ "github.com/restic/restic/internal/backend/sftp"
+	_ "github.com/restic/restic/internal/backend/swift"
+	_ "github.com/restic/restic/internal/backend/throttle"
+)
@@ -0,0 +1,63 @@
+package b2
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/restic/restic/internal/backend/location"
+	"github.com/restic/restic/internal/errors"
+	"github.com/restic/restic/internal/limiter"
+	"github.com/restic/restic/internal/restic"
+)
+
+func init() {
+	location.Register(factory{})
+}
+
+// factory implements location.Factory for the b2 backend.
+type factory struct{}
+
+func (factory) Scheme() string { return "b2" }
+
+func (factory) ParseConfig(s string) (interface{}, error) {
+	cfg, err := ParseConfig(s)
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (factory) StripPassword(s string) string { return location.NoPassword(s) }
+
+func (factory) ApplyEnvironment(c interface{}) error {
+	cfg := c.(*Config)
+
+	if cfg.AccountID == "" {
+		cfg.AccountID = os.Getenv("B2_ACCOUNT_ID")
+	}
+
+	if cfg.AccountID == "" {
+		return errors.Fatalf("unable to open B2 backend: Account ID ($B2_ACCOUNT_ID) is empty")
+	}
+
+	if cfg.Key == "" {
+		cfg.Key = os.Getenv("B2_ACCOUNT_KEY")
+	}
+
+	if cfg.Key == "" {
+		return errors.Fatalf("unable to open B2 backend: Key ($B2_ACCOUNT_KEY) is empty")
+	}
+
+	return nil
+}
+
+func (factory) UsesHTTP() bool { return true }
+
+func (factory) Create(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return Create(ctx, *cfg.(*Config), rt)
+}
+
+func (factory) Open(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return Open(ctx, *cfg.(*Config), rt)
+}
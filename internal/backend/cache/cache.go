@@ -0,0 +1,121 @@
+// Package cache wraps a backend with a read-through, on-disk copy of every
+// whole file it loads, keyed by the handle being loaded. Repacking and
+// similar operations frequently re-read the same pack file within a single
+// run (and across runs, for a resumed operation), so this turns those
+// repeat reads into local disk reads instead of round-trips to the backend.
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/restic/restic/internal/backend/location"
+	"github.com/restic/restic/internal/debug"
+	"github.com/restic/restic/internal/options"
+	"github.com/restic/restic/internal/restic"
+)
+
+func init() {
+	location.RegisterMiddleware(middleware{})
+}
+
+// middleware implements location.MiddlewareFactory for the "cache" scheme.
+// The cache directory can be set with "-o cache.path=/some/dir"; otherwise
+// it defaults to a directory under the user's cache dir, named after a hash
+// of the wrapped backend's location so that repeated runs against the same
+// repository reuse (rather than leak) the same cache directory.
+type middleware struct{}
+
+func (middleware) Scheme() string { return "cache" }
+
+func (middleware) Wrap(ctx context.Context, be restic.Backend, opts options.Options) (restic.Backend, error) {
+	dir := opts["path"]
+	if dir == "" {
+		var err error
+		dir, err = defaultCacheDir(be)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &cachedBackend{Backend: be, dir: dir}, nil
+}
+
+// defaultCacheDir returns a stable directory for caching files loaded from
+// be, derived from its location so that different repositories (or the same
+// repository accessed via different backend schemes) don't share a cache
+// directory, and so that restarting restic reuses rather than leaks one.
+func defaultCacheDir(be restic.Backend) (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(be.Location()))
+	return filepath.Join(base, "restic", "backend-cache", hex.EncodeToString(sum[:])), nil
+}
+
+type cachedBackend struct {
+	restic.Backend
+	dir string
+}
+
+func (b *cachedBackend) filename(h restic.Handle) string {
+	return filepath.Join(b.dir, string(h.Type), h.Name)
+}
+
+// Load serves whole-file reads from the on-disk cache, populating it from
+// the wrapped backend on a miss. Partial reads always go straight to the
+// wrapped backend, since caching a range would complicate invalidation for
+// little benefit.
+func (b *cachedBackend) Load(ctx context.Context, h restic.Handle, length int, offset int64, fn func(rd io.Reader) error) error {
+	if length != 0 || offset != 0 {
+		return b.Backend.Load(ctx, h, length, offset, fn)
+	}
+
+	if f, err := os.Open(b.filename(h)); err == nil {
+		defer f.Close()
+		return fn(f)
+	}
+
+	return b.Backend.Load(ctx, h, length, offset, func(rd io.Reader) error {
+		data, err := io.ReadAll(rd)
+		if err != nil {
+			return err
+		}
+
+		b.store(h, data)
+		return fn(bytes.NewReader(data))
+	})
+}
+
+func (b *cachedBackend) store(h restic.Handle, data []byte) {
+	name := b.filename(h)
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		debug.Log("cache: unable to create directory for %v: %v", name, err)
+		return
+	}
+
+	if err := os.WriteFile(name, data, 0600); err != nil {
+		debug.Log("cache: unable to write cache file %v: %v", name, err)
+	}
+}
+
+func (b *cachedBackend) Save(ctx context.Context, h restic.Handle, rd restic.RewindReader) error {
+	_ = os.Remove(b.filename(h))
+	return b.Backend.Save(ctx, h, rd)
+}
+
+func (b *cachedBackend) Remove(ctx context.Context, h restic.Handle) error {
+	_ = os.Remove(b.filename(h))
+	return b.Backend.Remove(ctx, h)
+}
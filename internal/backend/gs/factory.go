@@ -0,0 +1,48 @@
+package gs
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/restic/restic/internal/backend/location"
+	"github.com/restic/restic/internal/limiter"
+	"github.com/restic/restic/internal/restic"
+)
+
+func init() {
+	location.Register(factory{})
+}
+
+// factory implements location.Factory for the gs backend.
+type factory struct{}
+
+func (factory) Scheme() string { return "gs" }
+
+func (factory) ParseConfig(s string) (interface{}, error) {
+	cfg, err := ParseConfig(s)
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (factory) StripPassword(s string) string { return location.NoPassword(s) }
+
+func (factory) ApplyEnvironment(c interface{}) error {
+	cfg := c.(*Config)
+	if cfg.ProjectID == "" {
+		cfg.ProjectID = os.Getenv("GOOGLE_PROJECT_ID")
+	}
+	return nil
+}
+
+func (factory) UsesHTTP() bool { return true }
+
+func (factory) Create(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return Create(*cfg.(*Config), rt)
+}
+
+func (factory) Open(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return Open(*cfg.(*Config), rt)
+}
@@ -0,0 +1,41 @@
+package local
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/restic/restic/internal/backend/location"
+	"github.com/restic/restic/internal/limiter"
+	"github.com/restic/restic/internal/restic"
+)
+
+func init() {
+	location.Register(factory{})
+}
+
+// factory implements location.Factory for the local backend.
+type factory struct{}
+
+func (factory) Scheme() string { return "local" }
+
+func (factory) ParseConfig(s string) (interface{}, error) {
+	cfg, err := ParseConfig(s)
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (factory) StripPassword(s string) string { return location.NoPassword(s) }
+
+func (factory) ApplyEnvironment(cfg interface{}) error { return nil }
+
+func (factory) UsesHTTP() bool { return false }
+
+func (factory) Create(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return Create(ctx, *cfg.(*Config))
+}
+
+func (factory) Open(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return Open(ctx, *cfg.(*Config))
+}
@@ -3,15 +3,6 @@ package location
 import (
 	"strings"
 
-	"github.com/restic/restic/internal/backend/azure"
-	"github.com/restic/restic/internal/backend/b2"
-	"github.com/restic/restic/internal/backend/gs"
-	"github.com/restic/restic/internal/backend/local"
-	"github.com/restic/restic/internal/backend/rclone"
-	"github.com/restic/restic/internal/backend/rest"
-	"github.com/restic/restic/internal/backend/s3"
-	"github.com/restic/restic/internal/backend/sftp"
-	"github.com/restic/restic/internal/backend/swift"
 	"github.com/restic/restic/internal/errors"
 )
 
@@ -20,31 +11,11 @@ import (
 type location struct {
 	Scheme string
 	Config interface{}
-}
-
-type parser struct {
-	scheme        string
-	parse         func(string) (interface{}, error)
-	stripPassword func(string) string
-}
 
-// parsers is a list of valid config parsers for the backends. The first parser
-// is the fallback and should always be set to the local backend.
-var parsers = []parser{
-	{"b2", b2.ParseConfig, noPassword},
-	{"local", local.ParseConfig, noPassword},
-	{"sftp", sftp.ParseConfig, noPassword},
-	{"s3", s3.ParseConfig, noPassword},
-	{"gs", gs.ParseConfig, noPassword},
-	{"azure", azure.ParseConfig, noPassword},
-	{"swift", swift.ParseConfig, noPassword},
-	{"rest", rest.ParseConfig, rest.StripPassword},
-	{"rclone", rclone.ParseConfig, noPassword},
-}
-
-// noPassword returns the repository location unchanged (there's no sensitive information there)
-func noPassword(s string) string {
-	return s
+	// Middleware holds the chain of wrapping schemes that prefixed the
+	// backend scheme, outermost first, e.g. ["cache", "throttle"] for
+	// "cache:throttle:s3:bucket-name".
+	Middleware []string
 }
 
 func isPath(s string) bool {
@@ -79,19 +50,29 @@ func isPath(s string) bool {
 
 // parseLocation extracts repository location information from the string s.
 //
-// If s starts with a backend name followed by a colon, that backend's Parse()
-// function is called. Otherwise, the local backend is used which interprets s
-// as the name of a directory.
+// Any number of middleware schemes may prefix the backend scheme, each
+// separated by a colon, e.g. "cache:s3:bucket-name". These are peeled off
+// first and recorded in u.Middleware, outermost first.
+//
+// If what remains starts with a backend name followed by a colon, that
+// backend's Factory.ParseConfig is called. Otherwise, the local backend is used
+// which interprets s as the name of a directory.
 func parseLocation(s string) (u location, err error) {
+	for {
+		scheme, rest, ok := cutScheme(s)
+		if !ok || LookupMiddleware(scheme) == nil {
+			break
+		}
+
+		u.Middleware = append(u.Middleware, scheme)
+		s = rest
+	}
+
 	scheme := extractScheme(s)
 	u.Scheme = scheme
 
-	for _, parser := range parsers {
-		if parser.scheme != scheme {
-			continue
-		}
-
-		u.Config, err = parser.parse(s)
+	if factory := Lookup(scheme); factory != nil {
+		u.Config, err = factory.ParseConfig(s)
 		if err != nil {
 			return location{}, err
 		}
@@ -104,6 +85,11 @@ func parseLocation(s string) (u location, err error) {
 		return location{}, errors.New("invalid backend\nIf the repo is in a local directory, you need to add a `local:` prefix")
 	}
 
+	local := Lookup("local")
+	if local == nil {
+		return location{}, errors.New("invalid backend\nno \"local\" backend is registered to use as a fallback")
+	}
+
 	u.Scheme = "local"
 	u.Config, err = local.ParseConfig("local:" + s)
 	if err != nil {
@@ -115,13 +101,13 @@ func parseLocation(s string) (u location, err error) {
 
 // StripPassword returns a displayable version of a repository location (with any sensitive information removed)
 func StripPassword(s string) string {
-	scheme := extractScheme(s)
+	if scheme, rest, ok := cutScheme(s); ok && LookupMiddleware(scheme) != nil {
+		return scheme + ":" + StripPassword(rest)
+	}
 
-	for _, parser := range parsers {
-		if parser.scheme != scheme {
-			continue
-		}
-		return parser.stripPassword(s)
+	scheme := extractScheme(s)
+	if factory := Lookup(scheme); factory != nil {
+		return factory.StripPassword(s)
 	}
 	return s
 }
@@ -130,3 +116,16 @@ func extractScheme(s string) string {
 	data := strings.SplitN(s, ":", 2)
 	return data[0]
 }
+
+// cutScheme splits s into a leading "scheme:" prefix and the remainder,
+// analogous to strings.Cut(s, ":"). It reports ok == false if s has no
+// colon at all, so callers can tell a scheme prefix like "cache:..." apart
+// from a bare, unprefixed string that merely happens to equal a registered
+// scheme name (e.g. a local directory literally named "cache").
+func cutScheme(s string) (scheme, rest string, ok bool) {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+1:], true
+}
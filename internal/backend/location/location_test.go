@@ -0,0 +1,143 @@
+package location
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/restic/restic/internal/limiter"
+	"github.com/restic/restic/internal/options"
+	"github.com/restic/restic/internal/restic"
+)
+
+// fakeBackend is a minimal restic.Backend for exercising parseLocation,
+// StripPassword and wrapMiddleware without depending on any real backend
+// package (which would import this package and create a cycle).
+type fakeBackend struct {
+	restic.Backend
+	location string
+}
+
+func (b fakeBackend) Location() string { return b.location }
+
+type fakeFactory struct {
+	scheme string
+}
+
+func (f fakeFactory) Scheme() string { return f.scheme }
+
+func (f fakeFactory) ParseConfig(s string) (interface{}, error) {
+	cfg := s
+	return &cfg, nil
+}
+
+func (f fakeFactory) StripPassword(s string) string { return s }
+
+func (f fakeFactory) ApplyEnvironment(cfg interface{}) error { return nil }
+
+func (f fakeFactory) UsesHTTP() bool { return false }
+
+func (f fakeFactory) Create(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return fakeBackend{location: *cfg.(*string)}, nil
+}
+
+func (f fakeFactory) Open(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return fakeBackend{location: *cfg.(*string)}, nil
+}
+
+// tracingMiddleware wraps a backend's Location() with its own scheme name,
+// so tests can read the wrap order back off the final Location() value.
+type tracingMiddleware struct {
+	scheme string
+}
+
+func (m tracingMiddleware) Scheme() string { return m.scheme }
+
+func (m tracingMiddleware) Wrap(ctx context.Context, be restic.Backend, opts options.Options) (restic.Backend, error) {
+	return fakeBackend{location: m.scheme + "(" + be.Location() + ")"}, nil
+}
+
+func init() {
+	// Registered once for the whole package's test binary. "local" doubles
+	// as the fallback backend parseLocation uses for bare directory names.
+	Register(fakeFactory{scheme: "local"})
+	Register(fakeFactory{scheme: "remote"})
+
+	RegisterMiddleware(tracingMiddleware{scheme: "outer"})
+	RegisterMiddleware(tracingMiddleware{scheme: "inner"})
+}
+
+func TestParseLocation(t *testing.T) {
+	var tests = []struct {
+		s              string
+		wantScheme     string
+		wantMiddleware []string
+	}{
+		{"remote:foo", "remote", nil},
+		{"/some/local/dir", "local", nil},
+		{"local", "local", nil},
+		// A bare directory name that happens to collide with a registered
+		// middleware scheme must not be peeled as a prefix: there's no
+		// colon, so it isn't "<scheme>:<rest>", it's just a directory
+		// literally named "outer".
+		{"outer", "local", nil},
+		{"inner", "local", nil},
+		{"outer:remote:foo", "remote", []string{"outer"}},
+		{"outer:inner:remote:foo", "remote", []string{"outer", "inner"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			loc, err := parseLocation(test.s)
+			if err != nil {
+				t.Fatalf("parseLocation(%q) returned error: %v", test.s, err)
+			}
+			if loc.Scheme != test.wantScheme {
+				t.Errorf("parseLocation(%q).Scheme = %q, want %q", test.s, loc.Scheme, test.wantScheme)
+			}
+			if len(loc.Middleware) != len(test.wantMiddleware) {
+				t.Fatalf("parseLocation(%q).Middleware = %v, want %v", test.s, loc.Middleware, test.wantMiddleware)
+			}
+			for i := range test.wantMiddleware {
+				if loc.Middleware[i] != test.wantMiddleware[i] {
+					t.Errorf("parseLocation(%q).Middleware = %v, want %v", test.s, loc.Middleware, test.wantMiddleware)
+				}
+			}
+		})
+	}
+}
+
+func TestStripPasswordNoColonCollision(t *testing.T) {
+	// Regression test: a bare string equal to a registered middleware
+	// scheme, with no ":" anywhere in it, used to panic in the peel loop.
+	var tests = []string{"outer", "inner", "a"}
+
+	for _, s := range tests {
+		t.Run(s, func(t *testing.T) {
+			got := StripPassword(s)
+			if got != s {
+				t.Errorf("StripPassword(%q) = %q, want %q", s, got, s)
+			}
+		})
+	}
+}
+
+func TestWrapMiddlewareOrder(t *testing.T) {
+	loc := location{
+		Scheme:     "remote",
+		Middleware: []string{"outer", "inner"},
+	}
+
+	base := fakeBackend{location: "base"}
+	be, err := wrapMiddleware(context.Background(), base, loc, options.Options{})
+	if err != nil {
+		t.Fatalf("wrapMiddleware returned error: %v", err)
+	}
+
+	// "outer:inner:remote:..." must wrap as outer(inner(base)): the
+	// innermost scheme (closest to the backend) wraps first.
+	want := "outer(inner(base))"
+	if be.Location() != want {
+		t.Errorf("wrapMiddleware order = %q, want %q", be.Location(), want)
+	}
+}
@@ -0,0 +1,50 @@
+package location
+
+import (
+	"context"
+
+	"github.com/restic/restic/internal/options"
+	"github.com/restic/restic/internal/restic"
+)
+
+// A MiddlewareFactory wraps a restic.Backend with additional behavior, such
+// as caching, bandwidth throttling or request retries. Like a backend
+// Factory, a MiddlewareFactory registers itself for a URL scheme from its
+// own package's init() function (see RegisterMiddleware).
+//
+// A location may chain any number of middleware schemes in front of the
+// backend scheme, e.g. "cache:throttle:s3:bucket-name", each of which wraps
+// the backend (or the next middleware) returned by the one after it.
+type MiddlewareFactory interface {
+	// Scheme returns the URL scheme this middleware is responsible for,
+	// e.g. "cache" or "throttle".
+	Scheme() string
+
+	// Wrap returns be wrapped with this middleware's behavior. opts holds
+	// any "-o <scheme>.key=value" options the user passed for this
+	// middleware; it has already been extracted for this scheme.
+	Wrap(ctx context.Context, be restic.Backend, opts options.Options) (restic.Backend, error)
+}
+
+var middlewares = make(map[string]MiddlewareFactory)
+
+// RegisterMiddleware makes a MiddlewareFactory available under
+// factory.Scheme(). It is meant to be called from the init() function of a
+// middleware's package. RegisterMiddleware panics if a factory or
+// middleware for the same scheme was already registered.
+func RegisterMiddleware(factory MiddlewareFactory) {
+	scheme := factory.Scheme()
+	if _, ok := registry[scheme]; ok {
+		panic("location: middleware with scheme " + scheme + " collides with a registered backend")
+	}
+	if _, ok := middlewares[scheme]; ok {
+		panic("location: middleware with scheme " + scheme + " registered twice")
+	}
+	middlewares[scheme] = factory
+}
+
+// LookupMiddleware returns the MiddlewareFactory registered for scheme, or
+// nil if no middleware registered itself under that name.
+func LookupMiddleware(scheme string) MiddlewareFactory {
+	return middlewares[scheme]
+}
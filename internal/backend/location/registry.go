@@ -0,0 +1,75 @@
+package location
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/restic/restic/internal/limiter"
+	"github.com/restic/restic/internal/restic"
+)
+
+// A Factory knows how to parse, create and open one particular kind of
+// backend. Backends register a Factory for their scheme from an init()
+// function in their own package (see Register), so that third-party code
+// embedding restic can add support for additional backend types without
+// having to modify this package.
+type Factory interface {
+	// Scheme returns the URL scheme this factory is responsible for, e.g.
+	// "s3" or "local".
+	Scheme() string
+
+	// ParseConfig parses s, which must start with "<scheme>:", into a
+	// pointer to a backend-specific config struct. A pointer is required so
+	// that ApplyEnvironment and options.Options.Apply can fill in defaults.
+	ParseConfig(s string) (interface{}, error)
+
+	// StripPassword returns a version of s with any sensitive information
+	// removed, suitable for printing to a log or the console.
+	StripPassword(s string) string
+
+	// ApplyEnvironment fills in defaults for cfg (which must be the type
+	// returned by ParseConfig) from the process environment, e.g.
+	// credentials passed via $AWS_ACCESS_KEY_ID, and validates the result.
+	ApplyEnvironment(cfg interface{}) error
+
+	// Create creates a new backend. rt is nil unless UsesHTTP returns true.
+	// lim is passed through for the rare backend (e.g. rclone) that has to
+	// apply bandwidth limiting itself instead of through rt; most
+	// implementations can ignore it.
+	Create(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error)
+
+	// Open opens an existing backend. rt and lim are as for Create.
+	Open(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error)
+
+	// UsesHTTP reports whether the backend talks HTTP, so that callers know
+	// whether to build an http.RoundTripper (and wrap it for bandwidth
+	// limiting) before calling Create or Open, or whether to fall back to
+	// wrapping the returned restic.Backend with limiter.LimitBackend.
+	UsesHTTP() bool
+}
+
+var registry = make(map[string]Factory)
+
+// Register makes a backend Factory available under factory.Scheme(). It is
+// meant to be called from the init() function of a backend's package.
+// Register panics if a factory for the same scheme was already registered.
+func Register(factory Factory) {
+	scheme := factory.Scheme()
+	if _, ok := registry[scheme]; ok {
+		panic("location: backend with scheme " + scheme + " registered twice")
+	}
+	registry[scheme] = factory
+}
+
+// Lookup returns the Factory registered for scheme, or nil if no backend
+// registered itself under that name.
+func Lookup(scheme string) Factory {
+	return registry[scheme]
+}
+
+// NoPassword is a StripPassword implementation for backends whose location
+// string never contains sensitive information, so it can be printed
+// unchanged.
+func NoPassword(s string) string {
+	return s
+}
@@ -0,0 +1,45 @@
+package rclone
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/restic/restic/internal/backend/location"
+	"github.com/restic/restic/internal/limiter"
+	"github.com/restic/restic/internal/restic"
+)
+
+func init() {
+	location.Register(factory{})
+}
+
+// factory implements location.Factory for the rclone backend. rclone runs
+// as a subprocess and limits its own bandwidth, so it needs the limiter
+// directly instead of an http.RoundTripper.
+type factory struct{}
+
+func (factory) Scheme() string { return "rclone" }
+
+func (factory) ParseConfig(s string) (interface{}, error) {
+	cfg, err := ParseConfig(s)
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (factory) StripPassword(s string) string { return location.NoPassword(s) }
+
+func (factory) ApplyEnvironment(cfg interface{}) error { return nil }
+
+// UsesHTTP reports true so that location.Open doesn't double-wrap the
+// returned backend with limiter.LimitBackend: rclone applies lim itself.
+func (factory) UsesHTTP() bool { return true }
+
+func (factory) Create(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return Create(ctx, *cfg.(*Config))
+}
+
+func (factory) Open(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return Open(*cfg.(*Config), lim)
+}
@@ -0,0 +1,117 @@
+// Package retry wraps a backend's request methods (Save, Load, Stat, List,
+// Remove) in a bounded exponential-backoff retry loop. Flaky networks and
+// rate-limited object stores both tend to fail a request only
+// intermittently, and a single retry middleware here means every backend
+// gets that resilience for free instead of reimplementing its own loop.
+package retry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/restic/restic/internal/backend/location"
+	"github.com/restic/restic/internal/debug"
+	"github.com/restic/restic/internal/options"
+	"github.com/restic/restic/internal/restic"
+)
+
+func init() {
+	location.RegisterMiddleware(middleware{})
+}
+
+const defaultMaxTries = 10
+
+// middleware implements location.MiddlewareFactory for the "retry" scheme.
+// The number of attempts can be set with "-o retry.max-tries=N".
+type middleware struct{}
+
+func (middleware) Scheme() string { return "retry" }
+
+func (middleware) Wrap(ctx context.Context, be restic.Backend, opts options.Options) (restic.Backend, error) {
+	maxTries := defaultMaxTries
+	if s := opts["max-tries"]; s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 {
+			return nil, fmt.Errorf("retry.max-tries must be at least 1, got %d", n)
+		}
+		maxTries = n
+	}
+
+	return &backend{Backend: be, maxTries: maxTries}, nil
+}
+
+type backend struct {
+	restic.Backend
+	maxTries int
+}
+
+func (b *backend) retry(ctx context.Context, what string, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < b.maxTries; attempt++ {
+		err = fn()
+		if err == nil || b.Backend.IsNotExist(err) {
+			return err
+		}
+
+		debug.Log("retry: %v attempt %d failed: %v", what, attempt, err)
+
+		select {
+		case <-time.After(backoff(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return err
+}
+
+func backoff(attempt int) time.Duration {
+	d := time.Second << attempt
+	if d > time.Minute || d <= 0 {
+		d = time.Minute
+	}
+	return d
+}
+
+func (b *backend) Save(ctx context.Context, h restic.Handle, rd restic.RewindReader) error {
+	return b.retry(ctx, "Save", func() error {
+		if _, err := rd.Rewind(); err != nil {
+			return err
+		}
+		return b.Backend.Save(ctx, h, rd)
+	})
+}
+
+func (b *backend) Load(ctx context.Context, h restic.Handle, length int, offset int64, fn func(rd io.Reader) error) error {
+	return b.retry(ctx, "Load", func() error {
+		return b.Backend.Load(ctx, h, length, offset, fn)
+	})
+}
+
+func (b *backend) Stat(ctx context.Context, h restic.Handle) (restic.FileInfo, error) {
+	var fi restic.FileInfo
+	err := b.retry(ctx, "Stat", func() error {
+		var err error
+		fi, err = b.Backend.Stat(ctx, h)
+		return err
+	})
+	return fi, err
+}
+
+func (b *backend) Remove(ctx context.Context, h restic.Handle) error {
+	return b.retry(ctx, "Remove", func() error {
+		return b.Backend.Remove(ctx, h)
+	})
+}
+
+func (b *backend) List(ctx context.Context, t restic.FileType, fn func(restic.FileInfo) error) error {
+	return b.retry(ctx, "List", func() error {
+		return b.Backend.List(ctx, t, fn)
+	})
+}
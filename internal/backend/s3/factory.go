@@ -0,0 +1,65 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/restic/restic/internal/backend/location"
+	"github.com/restic/restic/internal/errors"
+	"github.com/restic/restic/internal/limiter"
+	"github.com/restic/restic/internal/restic"
+)
+
+func init() {
+	location.Register(factory{})
+}
+
+// factory implements location.Factory for the s3 backend.
+type factory struct{}
+
+func (factory) Scheme() string { return "s3" }
+
+func (factory) ParseConfig(s string) (interface{}, error) {
+	cfg, err := ParseConfig(s)
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (factory) StripPassword(s string) string { return location.NoPassword(s) }
+
+func (factory) ApplyEnvironment(c interface{}) error {
+	cfg := c.(*Config)
+
+	if cfg.KeyID == "" {
+		cfg.KeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+
+	if cfg.Secret == "" {
+		cfg.Secret = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+
+	if cfg.KeyID == "" && cfg.Secret != "" {
+		return errors.Fatalf("unable to open S3 backend: Key ID ($AWS_ACCESS_KEY_ID) is empty")
+	} else if cfg.KeyID != "" && cfg.Secret == "" {
+		return errors.Fatalf("unable to open S3 backend: Secret ($AWS_SECRET_ACCESS_KEY) is empty")
+	}
+
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	return nil
+}
+
+func (factory) UsesHTTP() bool { return true }
+
+func (factory) Create(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return Create(ctx, *cfg.(*Config), rt)
+}
+
+func (factory) Open(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return Open(ctx, *cfg.(*Config), rt)
+}
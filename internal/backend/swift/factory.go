@@ -0,0 +1,45 @@
+package swift
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/restic/restic/internal/backend/location"
+	"github.com/restic/restic/internal/limiter"
+	"github.com/restic/restic/internal/restic"
+)
+
+func init() {
+	location.Register(factory{})
+}
+
+// factory implements location.Factory for the swift backend. Swift has no
+// separate Create step, so Create and Open both just connect to the
+// container.
+type factory struct{}
+
+func (factory) Scheme() string { return "swift" }
+
+func (factory) ParseConfig(s string) (interface{}, error) {
+	cfg, err := ParseConfig(s)
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (factory) StripPassword(s string) string { return location.NoPassword(s) }
+
+func (factory) ApplyEnvironment(c interface{}) error {
+	return ApplyEnvironment("", c.(*Config))
+}
+
+func (factory) UsesHTTP() bool { return true }
+
+func (factory) Create(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return Open(ctx, *cfg.(*Config), rt)
+}
+
+func (factory) Open(ctx context.Context, cfg interface{}, rt http.RoundTripper, lim limiter.Limiter) (restic.Backend, error) {
+	return Open(ctx, *cfg.(*Config), rt)
+}
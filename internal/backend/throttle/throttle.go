@@ -0,0 +1,44 @@
+// Package throttle limits the bandwidth of the backend it wraps using
+// internal/limiter, the same mechanism behind the global
+// --limit-upload/--limit-download flags. Wiring it up as a middleware
+// instead lets a single repository URL carry its own bandwidth cap, which
+// matters when a restic invocation talks to several backends with
+// different limits at once (e.g. during "copy").
+package throttle
+
+import (
+	"context"
+
+	"github.com/restic/restic/internal/backend/location"
+	"github.com/restic/restic/internal/limiter"
+	"github.com/restic/restic/internal/options"
+	"github.com/restic/restic/internal/restic"
+	"github.com/restic/restic/internal/ui"
+)
+
+func init() {
+	location.RegisterMiddleware(middleware{})
+}
+
+// middleware implements location.MiddlewareFactory for the "throttle"
+// scheme. The bandwidth limit is configured with "-o throttle.limit=1MiB",
+// applied to both uploads and downloads.
+type middleware struct{}
+
+func (middleware) Scheme() string { return "throttle" }
+
+func (middleware) Wrap(ctx context.Context, be restic.Backend, opts options.Options) (restic.Backend, error) {
+	limitStr := opts["limit"]
+	if limitStr == "" {
+		return be, nil
+	}
+
+	limit, err := ui.ParseBytes(limitStr)
+	if err != nil {
+		return nil, err
+	}
+
+	kb := int(limit / 1024)
+	lim := limiter.NewStaticLimiter(limiter.Limits{UploadKb: kb, DownloadKb: kb})
+	return limiter.LimitBackend(be, lim), nil
+}
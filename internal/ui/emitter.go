@@ -0,0 +1,35 @@
+package ui
+
+import "github.com/restic/restic/internal/debug"
+
+// A Sink delivers every Event it is given somewhere: a terminal, a log
+// file, a socket that external tooling can connect to.
+type Sink interface {
+	Write(Event) error
+}
+
+// An Emitter publishes Events to one or more Sinks.
+type Emitter interface {
+	Emit(Event)
+}
+
+// multiEmitter is the Emitter returned by NewEmitter. It fans every Event
+// out to all of its sinks. A sink that returns an error is logged via
+// debug.Log rather than propagated, so that one failing consumer (e.g. a
+// disconnected socket reader) never stops the operation producing events.
+type multiEmitter struct {
+	sinks []Sink
+}
+
+// NewEmitter returns an Emitter that writes every Event to each of sinks.
+func NewEmitter(sinks ...Sink) Emitter {
+	return &multiEmitter{sinks: sinks}
+}
+
+func (e *multiEmitter) Emit(ev Event) {
+	for _, sink := range e.sinks {
+		if err := sink.Write(ev); err != nil {
+			debug.Log("ui: sink %T failed to write %s event: %v", sink, ev.MessageType(), err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package ui
+
+// schemaVersion is included in every Event so that a consumer can tell which
+// revision of an event's fields it is looking at. Bump it only when an
+// existing event type's JSON shape changes incompatibly; adding a new event
+// type, or new fields to an existing one, doesn't require a bump.
+const schemaVersion = 1
+
+// MessageType discriminates the JSON shape of an Event. Every concrete event
+// below sets it to a fixed value via its "message_type" field, so a consumer
+// reading the NDJSON stream can decide how to decode a line before doing so.
+type MessageType string
+
+// The message types restic currently emits. New types may be added in later
+// releases; consumers should ignore message types they don't recognize
+// rather than treat them as an error.
+const (
+	MessageTypeBackupProgress MessageType = "backup_progress"
+	MessageTypeSnapshotDone   MessageType = "snapshot_done"
+	MessageTypeRepoOpened     MessageType = "repo_opened"
+	MessageTypeBackendError   MessageType = "backend_error"
+)
+
+// Event is implemented by every status/progress message restic can emit to
+// an Emitter. MessageType lets a Sink (or an external consumer of the NDJSON
+// stream) dispatch on the concrete type without reflection.
+type Event interface {
+	MessageType() MessageType
+}
+
+// header is embedded in every concrete Event so that "message_type" and
+// "version" are always present in the encoded JSON, in the same position.
+type header struct {
+	Type    MessageType `json:"message_type"`
+	Version int         `json:"version"`
+}
+
+func newHeader(t MessageType) header {
+	return header{Type: t, Version: schemaVersion}
+}
+
+// BackupProgress reports incremental progress of a running backup.
+//
+//	{"message_type":"backup_progress","version":1,"files_done":12,
+//	 "files_total":40,"bytes_done":1048576,"bytes_total":4194304,
+//	 "seconds_elapsed":3,"percent_done":25}
+type BackupProgress struct {
+	header
+	FilesDone      uint64  `json:"files_done"`
+	FilesTotal     uint64  `json:"files_total"`
+	BytesDone      uint64  `json:"bytes_done"`
+	BytesTotal     uint64  `json:"bytes_total"`
+	SecondsElapsed uint64  `json:"seconds_elapsed"`
+	PercentDone    float64 `json:"percent_done"`
+}
+
+// NewBackupProgress creates a BackupProgress event. PercentDone is derived
+// from bytesDone/bytesTotal and is zero while bytesTotal is still unknown.
+func NewBackupProgress(filesDone, filesTotal, bytesDone, bytesTotal, secondsElapsed uint64) BackupProgress {
+	var percent float64
+	if bytesTotal > 0 {
+		percent = 100 * float64(bytesDone) / float64(bytesTotal)
+	}
+
+	return BackupProgress{
+		header:         newHeader(MessageTypeBackupProgress),
+		FilesDone:      filesDone,
+		FilesTotal:     filesTotal,
+		BytesDone:      bytesDone,
+		BytesTotal:     bytesTotal,
+		SecondsElapsed: secondsElapsed,
+		PercentDone:    percent,
+	}
+}
+
+func (BackupProgress) MessageType() MessageType { return MessageTypeBackupProgress }
+
+// SnapshotDone reports that a backup finished and a new snapshot was
+// written.
+//
+//	{"message_type":"snapshot_done","version":1,"snapshot_id":"a1b2c3",
+//	 "files_new":3,"files_changed":1,"files_unmodified":36,
+//	 "data_added":65536,"total_duration":12.5}
+type SnapshotDone struct {
+	header
+	SnapshotID      string  `json:"snapshot_id"`
+	FilesNew        uint64  `json:"files_new"`
+	FilesChanged    uint64  `json:"files_changed"`
+	FilesUnmodified uint64  `json:"files_unmodified"`
+	DataAdded       uint64  `json:"data_added"`
+	TotalDuration   float64 `json:"total_duration"`
+}
+
+// NewSnapshotDone creates a SnapshotDone event for the snapshot snapshotID.
+func NewSnapshotDone(snapshotID string, filesNew, filesChanged, filesUnmodified, dataAdded uint64, totalDuration float64) SnapshotDone {
+	return SnapshotDone{
+		header:          newHeader(MessageTypeSnapshotDone),
+		SnapshotID:      snapshotID,
+		FilesNew:        filesNew,
+		FilesChanged:    filesChanged,
+		FilesUnmodified: filesUnmodified,
+		DataAdded:       dataAdded,
+		TotalDuration:   totalDuration,
+	}
+}
+
+func (SnapshotDone) MessageType() MessageType { return MessageTypeSnapshotDone }
+
+// RepoOpened reports that a repository was opened successfully, so
+// consumers can correlate later events with a repository ID and check they
+// understand its format version.
+//
+//	{"message_type":"repo_opened","version":1,
+//	 "repository_id":"a1b2c3...","repository_version":2}
+type RepoOpened struct {
+	header
+	RepositoryID      string `json:"repository_id"`
+	RepositoryVersion uint   `json:"repository_version"`
+}
+
+// NewRepoOpened creates a RepoOpened event.
+func NewRepoOpened(repositoryID string, repositoryVersion uint) RepoOpened {
+	return RepoOpened{
+		header:            newHeader(MessageTypeRepoOpened),
+		RepositoryID:      repositoryID,
+		RepositoryVersion: repositoryVersion,
+	}
+}
+
+func (RepoOpened) MessageType() MessageType { return MessageTypeRepoOpened }
+
+// BackendError reports an error returned by the backend, so that monitoring
+// can alert on persistent backend trouble without scraping log output.
+// Retrying is true when restic will retry the operation itself; a consumer
+// only needs to act on errors where it's false, or that keep recurring.
+//
+//	{"message_type":"backend_error","version":1,"operation":"Save",
+//	 "retrying":true,"error":"connection reset by peer"}
+type BackendError struct {
+	header
+	Operation string `json:"operation"`
+	Retrying  bool   `json:"retrying"`
+	Error     string `json:"error"`
+}
+
+// NewBackendError creates a BackendError event for err, encountered while
+// performing operation.
+func NewBackendError(operation string, retrying bool, err error) BackendError {
+	return BackendError{
+		header:    newHeader(MessageTypeBackendError),
+		Operation: operation,
+		Retrying:  retrying,
+		Error:     err.Error(),
+	}
+}
+
+func (BackendError) MessageType() MessageType { return MessageTypeBackendError }
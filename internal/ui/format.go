@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/bits"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -35,6 +37,41 @@ func FormatBytes(c uint64) string {
 	return string(s)
 }
 
+// FormatBytesSI formats c like FormatBytes, but using SI (decimal,
+// powers-of-1000) units, e.g. "1.500 GB" rather than "1.397 GiB", for
+// operators who prefer decimal sizes.
+func FormatBytesSI(c uint64) string {
+	s := make([]byte, 0, 16)
+
+	const (
+		kb = 1000
+		mb = kb * 1000
+		gb = mb * 1000
+		tb = gb * 1000
+	)
+
+	div, unit := uint64(0), ""
+	switch {
+	case c >= tb:
+		div, unit = tb, " TB"
+	case c >= gb:
+		div, unit = gb, " GB"
+	case c >= mb:
+		div, unit = mb, " MB"
+	case c >= kb:
+		div, unit = kb, " kB"
+	default:
+		s = strconv.AppendUint(s, c, 10)
+		s = append(s, " B"...)
+		return string(s)
+	}
+
+	b := float64(c) / float64(div)
+	s = strconv.AppendFloat(s, b, 'f', 3, 64)
+	s = append(s, unit...)
+	return string(s)
+}
+
 // FormatPercent formats numerator/denominator as a percentage.
 func FormatPercent(numerator uint64, denominator uint64) string {
 	if denominator == 0 {
@@ -68,44 +105,109 @@ func FormatSeconds(sec uint64) string {
 	return fmt.Sprintf("%d:%02d", min, sec)
 }
 
-// ParseBytes parses a size in bytes from s. It understands the suffixes
-// B, K, M, G and T for powers of 1024.
+// Errors returned by ParseBytes, so that callers (e.g. cmd/restic flag
+// parsing) can give more specific diagnostics than a bare error string.
+// Use errors.Is to test for them; overflow reuses strconv.ErrRange since
+// it's exactly that.
+var (
+	// ErrUnknownUnit means s had a unit suffix ParseBytes doesn't know.
+	ErrUnknownUnit = errors.New("unknown unit")
+	// ErrInvalidSize means s wasn't a number, optionally followed by a unit.
+	ErrInvalidSize = errors.New("invalid size")
+)
+
+// unitMultipliers maps a lowercased unit suffix to its multiplier. A bare
+// letter (k, m, g, t, p) and its IEC spelling (ki, kib, ...) are powers of
+// 1024; the explicit SI spelling (kb, mb, ...) is a power of 1000. All of
+// them fit comfortably in an int64, which lets ParseBytes do exact integer
+// arithmetic for plain integer input instead of routing it through float64.
+var unitMultipliers = map[string]int64{
+	"b": 1,
+
+	"k": 1 << 10, "ki": 1 << 10, "kib": 1 << 10, "kb": 1e3,
+	"m": 1 << 20, "mi": 1 << 20, "mib": 1 << 20, "mb": 1e6,
+	"g": 1 << 30, "gi": 1 << 30, "gib": 1 << 30, "gb": 1e9,
+	"t": 1 << 40, "ti": 1 << 40, "tib": 1 << 40, "tb": 1e12,
+	"p": 1 << 50, "pi": 1 << 50, "pib": 1 << 50, "pb": 1e15,
+}
+
+// ParseBytes parses a size in bytes from s, e.g. "10MiB", "1.5G" or
+// "2000000". The number may be fractional and may be followed by optional
+// whitespace and a unit: a bare letter or its "i"/"iB" IEC spelling (K, Ki,
+// KiB, ...) for powers of 1024, or an explicit SI spelling (KB, MB, ...) for
+// powers of 1000. Units are case-insensitive.
+//
+// A plain (non-fractional) number is multiplied exactly using integer
+// arithmetic, so large values like "9007199254740993" don't silently lose
+// precision the way they would going through float64; only a number with a
+// fractional part pays for that imprecision, which is unavoidable there.
 func ParseBytes(s string) (int64, error) {
 	if s == "" {
-		return 0, errors.New("expected size, got empty string")
+		return 0, fmt.Errorf("%w: expected size, got empty string", ErrInvalidSize)
 	}
 
-	numStr := s[:len(s)-1]
-	var unit uint64 = 1
-
-	switch s[len(s)-1] {
-	case 'b', 'B':
-		// use initialized values, do nothing here
-	case 'k', 'K':
-		unit = 1024
-	case 'm', 'M':
-		unit = 1024 * 1024
-	case 'g', 'G':
-		unit = 1024 * 1024 * 1024
-	case 't', 'T':
-		unit = 1024 * 1024 * 1024 * 1024
-	default:
-		numStr = s
+	i := 0
+	for i < len(s) && (s[i] == '+' || s[i] == '-' || s[i] == '.' || (s[i] >= '0' && s[i] <= '9')) {
+		i++
 	}
-	value, err := strconv.ParseInt(numStr, 10, 64)
+	numStr := s[:i]
+	if numStr == "" {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSize, s)
+	}
+
+	unit := strings.ToLower(strings.TrimSpace(s[i:]))
+	mult := int64(1)
+	if unit != "" {
+		m, ok := unitMultipliers[unit]
+		if !ok {
+			return 0, fmt.Errorf("%w: %q", ErrUnknownUnit, unit)
+		}
+		mult = m
+	}
+
+	if !strings.ContainsRune(numStr, '.') {
+		value, err := strconv.ParseInt(numStr, 10, 64)
+		if err != nil {
+			if numErr, ok := err.(*strconv.NumError); ok && errors.Is(numErr.Err, strconv.ErrRange) {
+				return 0, fmt.Errorf("ParseBytes: %q: %w", s, strconv.ErrRange)
+			}
+			return 0, fmt.Errorf("%w: %q", ErrInvalidSize, s)
+		}
+		if value < 0 {
+			return 0, fmt.Errorf("%w: negative size %q", ErrInvalidSize, s)
+		}
+
+		hi, lo := bits.Mul64(uint64(value), uint64(mult))
+		if hi != 0 || lo > math.MaxInt64 {
+			return 0, fmt.Errorf("ParseBytes: %q: %w", s, strconv.ErrRange)
+		}
+
+		return int64(lo), nil
+	}
+
+	value, err := strconv.ParseFloat(numStr, 64)
 	if err != nil {
-		return 0, err
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSize, s)
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("%w: negative size %q", ErrInvalidSize, s)
 	}
 
-	hi, lo := bits.Mul64(uint64(value), unit)
-	value = int64(lo)
-	if hi != 0 || value < 0 {
-		return 0, fmt.Errorf("ParseSize: %q: %w", numStr, strconv.ErrRange)
+	result := value * float64(mult)
+	if result >= math.MaxInt64 {
+		return 0, fmt.Errorf("ParseBytes: %q: %w", s, strconv.ErrRange)
 	}
 
-	return value, nil
+	return int64(result), nil
 }
 
+// ToJSONString encodes status as a single line of JSON. It predates the
+// typed Event/Emitter system in events.go and emitter.go; existing status
+// producers outside this package still call it directly, so it stays until
+// they're migrated to Emit(Event) instead.
+//
+// Deprecated: new code should define a typed Event and call Emitter.Emit
+// instead of assembling an ad-hoc interface{} here.
 func ToJSONString(status interface{}) string {
 	buf := new(bytes.Buffer)
 	err := json.NewEncoder(buf).Encode(status)
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestParseBytes(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  int64
+	}{
+		{"0", 0},
+		{"1024", 1024},
+		{"10B", 10},
+		{"10K", 10 * 1024},
+		{"10KiB", 10 * 1024},
+		{"10KB", 10 * 1000},
+		{"1.5G", int64(1.5 * (1 << 30))},
+		{"1.5GiB", int64(1.5 * (1 << 30))},
+		{"1.5GB", int64(1.5 * 1e9)},
+		{"5 MiB", 5 * (1 << 20)},
+		{"9007199254740993", 9007199254740993}, // not exactly representable as a float64
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, err := ParseBytes(test.input)
+			if err != nil {
+				t.Fatalf("ParseBytes(%q) returned error: %v", test.input, err)
+			}
+			if got != test.want {
+				t.Errorf("ParseBytes(%q) = %v, want %v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseBytesErrors(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  error
+	}{
+		{"", ErrInvalidSize},
+		{"abc", ErrInvalidSize},
+		{"-5", ErrInvalidSize},
+		{"-1.5G", ErrInvalidSize},
+		{"10XB", ErrUnknownUnit},
+		{"99999999999999999999999999", strconv.ErrRange},
+		{"99999999999999999999999999.0", strconv.ErrRange},
+		{"100000000000000000000PB", strconv.ErrRange},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			_, err := ParseBytes(test.input)
+			if err == nil {
+				t.Fatalf("ParseBytes(%q) returned no error, want one matching %v", test.input, test.want)
+			}
+			if !errors.Is(err, test.want) {
+				t.Errorf("ParseBytes(%q) returned error %v, want one matching %v", test.input, err, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	var tests = []struct {
+		input uint64
+		want  string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.000 KiB"},
+		{1 << 30, "1.000 GiB"},
+	}
+
+	for _, test := range tests {
+		if got := FormatBytes(test.input); got != test.want {
+			t.Errorf("FormatBytes(%v) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestFormatBytesSI(t *testing.T) {
+	var tests = []struct {
+		input uint64
+		want  string
+	}{
+		{0, "0 B"},
+		{999, "999 B"},
+		{1000, "1.000 kB"},
+		{1_500_000_000, "1.500 GB"},
+	}
+
+	for _, test := range tests {
+		if got := FormatBytesSI(test.input); got != test.want {
+			t.Errorf("FormatBytesSI(%v) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"sync"
+)
+
+// WriterSink writes each Event as a single line of JSON (NDJSON) to w. It
+// backs both the default JSON-lines-to-stdout sink and NDJSON-to-file
+// logging; the two only differ in which io.Writer they wrap.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink returns a Sink that writes NDJSON events to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (s *WriterSink) Write(ev Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.NewEncoder(s.w).Encode(ev)
+}
+
+// NewFileSink opens (creating if necessary, and appending if it already
+// exists) the file at path and returns a Sink that writes NDJSON events to
+// it.
+func NewFileSink(path string) (*WriterSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWriterSink(f), nil
+}
+
+// SocketSink streams NDJSON events over a Unix socket, so that an external
+// UI (a GUI progress window, a supervisor) can connect and follow the event
+// stream live instead of scraping stdout. Events written before any client
+// has connected are dropped.
+type SocketSink struct {
+	listener net.Listener
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+// NewSocketSink creates a Unix socket at path, removing any stale socket
+// file left behind at that path, and returns a Sink that broadcasts every
+// Event to all currently-connected clients.
+func NewSocketSink(path string) (*SocketSink, error) {
+	_ = os.Remove(path)
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SocketSink{listener: l}
+	go s.acceptLoop()
+	return s, nil
+}
+
+func (s *SocketSink) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+	}
+}
+
+func (s *SocketSink) Write(ev Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	live := s.conns[:0]
+	for _, conn := range s.conns {
+		if _, err := conn.Write(data); err != nil {
+			conn.Close()
+			continue
+		}
+		live = append(live, conn)
+	}
+	s.conns = live
+
+	return nil
+}
+
+// Close stops accepting new clients and disconnects all currently connected
+// ones.
+func (s *SocketSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+
+	return s.listener.Close()
+}